@@ -0,0 +1,35 @@
+package commands
+
+import "text/template"
+
+// defaultTemplateSources holds the raw template text registered via
+// RegisterDefaultTemplate, keyed the same way componentType.Templates
+// maps a role to a template lookup key (e.g. "template_controller").
+var defaultTemplateSources = map[string]string{}
+
+// RegisterDefaultTemplate registers source as the compiled-in default
+// for key, used by loadTemplate whenever a project has no
+// .gogo/templates override for it. A component type that ships its own
+// default calls this from its init(), mirroring
+// RegisterComponentType/RegisterComponentAlias.
+func RegisterDefaultTemplate(key, source string) {
+	defaultTemplateSources[key] = source
+}
+
+// templateBox looks up a registered default template by key, parsing it
+// lazily so a bad RegisterDefaultTemplate source only fails the
+// generate call that actually needs it.
+type templateBox struct{}
+
+// Lookup returns the parsed default template for key, or nil if nothing
+// was registered for it.
+func (templateBox) Lookup(key string) *template.Template {
+	source, ok := defaultTemplateSources[key]
+	if !ok {
+		return nil
+	}
+
+	return template.Must(template.New(key).Parse(source))
+}
+
+var box = templateBox{}
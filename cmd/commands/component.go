@@ -1,109 +1,315 @@
 package commands
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
+	"sort"
 	"strings"
+	"text/template"
 
 	"github.com/dolab/gogo/pkgs/named"
 	"github.com/golib/cli"
 )
 
+// builtin component type names, kept for backwards compatibility with
+// callers that still refer to them by string.
 const (
-	_comType ComponentType = iota
-	ComTypeController
-	ComTypeMiddleware
-	ComTypeModel
-	comType_
+	ComTypeController = "controller"
+	ComTypeMiddleware = "middleware"
+	ComTypeModel      = "model"
 )
 
+// templatesOverrideDir is the project-local directory used to override
+// built-in (or third-party) templates without recompiling gogo.
+const templatesOverrideDir = ".gogo/templates"
+
 var (
-	Component *_Component
+	Component *_Component = &_Component{}
 
-	comDirs = map[ComponentType][]string{
-		ComTypeController: {"app", "controllers"},
-		ComTypeMiddleware: {"app", "middlewares"},
-		ComTypeModel:      {"app", "models"},
-	}
+	// componentTypes holds every registered component type, keyed by
+	// name. Built-ins are registered in init(); RegisterComponentType
+	// lets users and internal callers add their own (service, task,
+	// migration, grpc, subscriber, ...).
+	componentTypes = map[string]*componentType{}
 )
 
-type ComponentType int
+func init() {
+	RegisterComponentType(ComTypeController, []string{"app", "controllers"}, map[string]string{
+		"source": "template_controller",
+		"test":   "template_controller_test",
+	}, []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "actions",
+			Usage: "specify actions to generating, defaults to gogo resources.",
+			Value: &cli.StringSlice{"index", "create", "show", "update", "destroy"},
+		},
+		cli.StringSliceFlag{
+			Name:  "import",
+			Usage: "specify extra import paths for the generated controller, available to its template as .Imports.",
+		},
+	})
+	RegisterComponentAlias(ComTypeController, "c")
+	RegisterDefaultTemplate("template_controller", controllerSourceTemplate)
+	RegisterDefaultTemplate("template_controller_test", controllerTestTemplate)
+
+	RegisterComponentType(ComTypeMiddleware, []string{"app", "middlewares"}, map[string]string{
+		"source": "template_middleware",
+		"test":   "template_middleware_test",
+	}, nil)
+	RegisterComponentAlias(ComTypeMiddleware, "w")
+	RegisterDefaultTemplate("template_middleware", middlewareSourceTemplate)
+	RegisterDefaultTemplate("template_middleware_test", middlewareTestTemplate)
+
+	RegisterComponentType(ComTypeModel, []string{"app", "models"}, map[string]string{
+		"source": "template_model",
+		"test":   "template_model_test",
+	}, nil)
+	RegisterComponentAlias(ComTypeModel, "m")
+	RegisterDefaultTemplate("template_model", modelSourceTemplate)
+	RegisterDefaultTemplate("template_model_test", modelTestTemplate)
+}
+
+// controllerSourceTemplate and controllerTestTemplate are the compiled-in
+// defaults for "controller", rendering one gogo.Context handler per
+// requested action. .Fields.Actions holds .Args capitalized into Go
+// identifiers (see newComponent), since actions are passed lowercase
+// (e.g. "index") by the "-actions" flag. .Imports holds any extra import
+// paths passed via "-import", e.g. a validation package the generated
+// actions need.
+const controllerSourceTemplate = `package controllers
 
-func (ct ComponentType) Valid() bool {
-	return ct > _comType && ct < comType_
+import (
+	"github.com/dolab/gogo"
+{{range .Imports}}	"{{.}}"
+{{end}})
+
+// {{.Name}}Controller implements the {{.Name}} resource actions.
+type {{.Name}}Controller struct{}
+{{range $i, $action := .Args}}
+// {{index $.Fields.Actions $i}} handles the {{$action}} action.
+func (_ *{{$.Name}}Controller) {{index $.Fields.Actions $i}}(ctx *gogo.Context) {
+	ctx.Text("{{$.Name}}#{{$action}}")
 }
+{{end}}`
 
-func (ct ComponentType) Root(pwd string) string {
-	dirs, ok := comDirs[ct]
-	if !ok {
-		return pwd
+const controllerTestTemplate = `package controllers
+
+import (
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_{{.Name}}Controller(t *testing.T) {
+	it := assert.New(t)
+
+	controller := &{{.Name}}Controller{}
+	it.NotNil(controller)
+}
+`
+
+// middlewareSourceTemplate and middlewareTestTemplate are the compiled-in
+// defaults for "middleware".
+const middlewareSourceTemplate = `package middlewares
+
+import (
+	"github.com/dolab/gogo"
+)
+
+// New{{.Name}}Middleware returns a gogo.HandlerFunc a Service's
+// Middlewares can register with its Grouper via Use.
+func New{{.Name}}Middleware() gogo.HandlerFunc {
+	return func(ctx *gogo.Context) {
+		ctx.Next()
 	}
+}
+`
 
-	pwd = strings.TrimSuffix(pwd, "/")
-	pwd = strings.TrimSuffix(pwd, "/gogo")
+const middlewareTestTemplate = `package middlewares
+
+import (
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_New{{.Name}}Middleware(t *testing.T) {
+	it := assert.New(t)
 
-	return path.Clean(path.Join(pwd, "gogo", path.Join(dirs...)))
+	middleware := New{{.Name}}Middleware()
+	it.NotNil(middleware)
 }
+`
 
-func (ct ComponentType) String() string {
-	switch ct {
-	case ComTypeController:
-		return "controller"
+// modelSourceTemplate and modelTestTemplate are the compiled-in defaults
+// for "model".
+const modelSourceTemplate = `package models
 
-	case ComTypeMiddleware:
-		return "middleware"
+// {{.Name}} is a model.
+type {{.Name}} struct{}
+`
 
-	case ComTypeModel:
-		return "model"
+const modelTestTemplate = `package models
 
+import (
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_{{.Name}}(t *testing.T) {
+	it := assert.New(t)
+
+	model := &{{.Name}}{}
+	it.NotNil(model)
+}
+`
+
+// componentAliases holds the short subcommand aliases (e.g. "c" for
+// "controller") registered via RegisterComponentAlias, keyed by
+// component type name.
+var componentAliases = map[string][]string{}
+
+// RegisterComponentAlias registers an additional short alias for a
+// previously registered component type's "generate <name>" subcommand,
+// e.g. RegisterComponentAlias("controller", "c").
+func RegisterComponentAlias(name, alias string) {
+	componentAliases[name] = append(componentAliases[name], alias)
+}
+
+// componentType describes a scaffoldable component: where it lives in a
+// gogo project, which templates render its files and which extra flags
+// its "generate <name>" subcommand accepts.
+type componentType struct {
+	Name string
+	Dirs []string
+
+	// Templates maps a logical role (e.g. "source", "test") to the
+	// template lookup key. The key is tried against the project's
+	// .gogo/templates override directory first, falling back to the
+	// compiled-in template box.
+	Templates map[string]string
+
+	Flags []cli.Flag
+}
+
+// RegisterComponentType registers a new scaffoldable component type, e.g.
+//
+//	commands.RegisterComponentType("service", []string{"app", "services"}, map[string]string{
+//		"source": "template_service",
+//		"test":   "template_service_test",
+//	}, nil)
+//
+// Registering a name that already exists overwrites it, so projects may
+// also use it to customize a built-in type (controller, middleware,
+// model) without forking gogo.
+func RegisterComponentType(name string, dirs []string, templates map[string]string, flags []cli.Flag) {
+	componentTypes[name] = &componentType{
+		Name:      name,
+		Dirs:      dirs,
+		Templates: templates,
+		Flags:     flags,
 	}
+}
+
+// lookupComponentType returns the registered component type by name.
+func lookupComponentType(name string) (*componentType, bool) {
+	ct, ok := componentTypes[name]
+	return ct, ok
+}
+
+// Root resolves the destination directory of a component within a gogo
+// project rooted at pwd.
+func (ct *componentType) Root(pwd string) string {
+	pwd = ct.projectRoot(pwd)
+
+	return path.Clean(path.Join(pwd, path.Join(ct.Dirs...)))
+}
 
-	return ""
+// projectRoot resolves the gogo project root (the directory containing
+// app/, config/ and .gogo/) from anywhere beneath it.
+func (ct *componentType) projectRoot(pwd string) string {
+	pwd = strings.TrimSuffix(pwd, "/")
+	pwd = strings.TrimSuffix(pwd, "/gogo")
+
+	return path.Join(pwd, "gogo")
 }
 
+// ComTemplateModel is the data exposed to component templates. Fields,
+// Flags and Imports let a custom component type surface arbitrary
+// template data beyond the historical {Name, Args} shape.
 type ComTemplateModel struct {
 	Name string
 	Args []string
+
+	Fields  map[string]interface{}
+	Flags   map[string]interface{}
+	Imports []string
+}
+
+// ComTemplateOption customizes the ComTemplateModel handed to a
+// component's templates before they're executed.
+type ComTemplateOption func(*ComTemplateModel)
+
+// WithFields attaches arbitrary named fields to the template model,
+// available to templates as .Fields.
+func WithFields(fields map[string]interface{}) ComTemplateOption {
+	return func(model *ComTemplateModel) {
+		model.Fields = fields
+	}
+}
+
+// WithFlags attaches the resolved generate flags to the template model,
+// available to templates as .Flags.
+func WithFlags(flags map[string]interface{}) ComTemplateOption {
+	return func(model *ComTemplateModel) {
+		model.Flags = flags
+	}
+}
+
+// WithImports attaches extra import paths to the template model,
+// available to templates as .Imports.
+func WithImports(imports ...string) ComTemplateOption {
+	return func(model *ComTemplateModel) {
+		model.Imports = imports
+	}
 }
 
 type _Component struct{}
 
+// Command builds the "generate" command with one subcommand per
+// registered component type, so third-party types added via
+// RegisterComponentType show up in `gogo generate --help` alongside the
+// built-ins.
 func (_ *_Component) Command() cli.Command {
+	names := make([]string, 0, len(componentTypes))
+	for name := range componentTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	subcommands := make(cli.Commands, 0, len(names))
+	for _, name := range names {
+		name := name // capture for the closure below
+
+		subcommands = append(subcommands, cli.Command{
+			Name:    name,
+			Aliases: componentAliases[name],
+			Usage:   "generate " + name + " component.",
+			Flags:   componentTypes[name].Flags,
+			Action:  Component.NewComponent(name),
+		})
+	}
+
 	return cli.Command{
-		Name:    "generate",
-		Aliases: []string{"g"},
-		Usage:   "generate controller and model components.",
-		Flags:   Component.Flags(),
-		Action:  Component.Action(),
-		Subcommands: cli.Commands{
-			{
-				Name:    "controller",
-				Aliases: []string{"c"},
-				Usage:   "generate controller component.",
-				Flags: []cli.Flag{
-					cli.StringSliceFlag{
-						Name:  "actions",
-						Usage: "specify actions to generating, defaults to gogo resources.",
-						Value: &cli.StringSlice{"index", "create", "show", "update", "destroy"},
-					},
-				},
-				Action: Component.NewController(),
-			},
-			{
-				Name:    "middleware",
-				Aliases: []string{"w"},
-				Usage:   "generate middleware component.",
-				Flags:   []cli.Flag{},
-				Action:  Component.NewMiddleware(),
-			},
-			{
-				Name:    "model",
-				Aliases: []string{"m"},
-				Usage:   "generate model component.",
-				Flags:   []cli.Flag{},
-				Action:  Component.NewModel(),
-			},
-		},
+		Name:        "generate",
+		Aliases:     []string{"g"},
+		Usage:       "generate controller and model components.",
+		Flags:       Component.Flags(),
+		Action:      Component.Action(),
+		Subcommands: subcommands,
 	}
 }
 
@@ -120,18 +326,21 @@ func (_ *_Component) Flags() []cli.Flag {
 	}
 }
 
+// Action scaffolds the default resource shape (controller + model) for
+// the bare `gogo generate NAME` invocation.
 func (_ *_Component) Action() cli.ActionFunc {
 	return func(ctx *cli.Context) error {
 		name := path.Clean(ctx.Args().First())
+		flags := Component.resolveFlags(ctx)
 
 		// controller
-		err := Component.newComponent(ComTypeController, name, ctx.StringSlice("controller-actions")...)
+		err := Component.newComponent(ComTypeController, name, ctx.StringSlice("controller-actions"), WithFlags(flags))
 		if err != nil {
 			return err
 		}
 
 		// model
-		err = Component.newComponent(ComTypeModel, name)
+		err = Component.newComponent(ComTypeModel, name, nil, WithFlags(flags))
 		if err != nil {
 			return err
 		}
@@ -140,6 +349,42 @@ func (_ *_Component) Action() cli.ActionFunc {
 	}
 }
 
+// NewComponent returns the cli.ActionFunc for a registered component
+// type's subcommand, e.g. `gogo generate service NAME`. Types that need
+// more than "render source/test templates" (grpc's protoc invocation)
+// register their own action in customComponentActions.
+func (_ *_Component) NewComponent(comName string) cli.ActionFunc {
+	if action, ok := customComponentActions[comName]; ok {
+		return action
+	}
+
+	return func(ctx *cli.Context) error {
+		name := path.Clean(ctx.Args().First())
+
+		args := ctx.StringSlice("actions")
+		if comName == ComTypeController && len(args) == 0 {
+			args = []string{"index", "create", "show", "update", "destroy"}
+		}
+
+		opts := []ComTemplateOption{WithFlags(Component.resolveFlags(ctx))}
+		if comName == ComTypeController {
+			opts = append(opts, WithImports(ctx.StringSlice("import")...))
+		}
+
+		return Component.newComponent(comName, name, args, opts...)
+	}
+}
+
+// customComponentActions holds component types whose generate
+// subcommand can't be expressed as a plain template render, keyed by
+// name. Populated by init() in the file that defines each such type
+// (see grpc.go).
+var customComponentActions = map[string]cli.ActionFunc{}
+
+// NewController generates a controller component, kept as a thin
+// wrapper around the registry-backed newComponent for callers that
+// still reference it directly. Unlike NewComponent, it does not pass
+// "-import" values through as .Imports.
 func (_ *_Component) NewController() cli.ActionFunc {
 	return func(ctx *cli.Context) error {
 		name := path.Clean(ctx.Args().First())
@@ -149,7 +394,7 @@ func (_ *_Component) NewController() cli.ActionFunc {
 			actions = []string{"index", "create", "show", "update", "destroy"}
 		}
 
-		return Component.newComponent(ComTypeController, name, actions...)
+		return Component.newComponent(ComTypeController, name, actions, WithFlags(Component.resolveFlags(ctx)))
 	}
 }
 
@@ -157,7 +402,7 @@ func (_ *_Component) NewMiddleware() cli.ActionFunc {
 	return func(ctx *cli.Context) error {
 		name := path.Clean(ctx.Args().First())
 
-		return Component.newComponent(ComTypeMiddleware, name)
+		return Component.newComponent(ComTypeMiddleware, name, nil, WithFlags(Component.resolveFlags(ctx)))
 	}
 }
 
@@ -165,12 +410,13 @@ func (_ *_Component) NewModel() cli.ActionFunc {
 	return func(ctx *cli.Context) error {
 		name := path.Clean(ctx.Args().First())
 
-		return Component.newComponent(ComTypeModel, name)
+		return Component.newComponent(ComTypeModel, name, nil, WithFlags(Component.resolveFlags(ctx)))
 	}
 }
 
-func (_ *_Component) newComponent(com ComponentType, name string, args ...string) error {
-	if !com.Valid() {
+func (_ *_Component) newComponent(comName, name string, args []string, opts ...ComTemplateOption) error {
+	ct, ok := lookupComponentType(comName)
+	if !ok {
 		return ErrComponentType
 	}
 
@@ -181,42 +427,65 @@ func (_ *_Component) newComponent(com ComponentType, name string, args ...string
 		return err
 	}
 
-	comRoot := com.Root(root)
+	comRoot := ct.Root(root)
 	if !strings.Contains(comRoot, "/gogo/") {
 		return ErrInvalidRoot
 	}
 
-	comName := name
 	comArgs := &ComTemplateModel{
-		Name: Component.toCamelCase(comName),
+		Name: Component.toCamelCase(name),
 		Args: args,
 	}
+	if comName == ComTypeController && len(args) > 0 {
+		WithFields(map[string]interface{}{
+			"Actions": Component.toExportedNames(args),
+		})(comArgs)
+	}
+	for _, opt := range opts {
+		opt(comArgs)
+	}
 
-	// generate xxx.go
-	fd, err := os.OpenFile(path.Join(comRoot, Component.toFilename(comName)), os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		stderr.Error(err.Error())
+	for _, role := range []string{"source", "test"} {
+		key, ok := ct.Templates[role]
+		if !ok {
+			continue
+		}
 
-		return err
+		filename := name
+		if role == "test" {
+			filename = name + "_test"
+		}
+
+		err := Component.renderTemplate(ct.projectRoot(root), key, path.Join(comRoot, Component.toFilename(filename)), comArgs)
+		if err != nil {
+			return err
+		}
 	}
 
-	err = box.Lookup("template_"+com.String()).Execute(fd, comArgs)
+	return nil
+}
+
+// renderTemplate executes the template looked up by key (honoring the
+// project's .gogo/templates override) into filename. It's the shared
+// rendering primitive newComponent and custom actions like grpc's
+// NewGRPC both build their file generation on.
+func (_ *_Component) renderTemplate(projectRoot, key, filename string, model *ComTemplateModel) error {
+	fd, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		stderr.Errorf(err.Error())
+		stderr.Error(err.Error())
 
 		return err
 	}
+	defer fd.Close()
 
-	// generate xxx_test.go
-	fd, err = os.OpenFile(path.Join(comRoot, Component.toFilename(comName+"_test")), os.O_CREATE|os.O_WRONLY, 0644)
+	tpl, err := Component.loadTemplate(projectRoot, key)
 	if err != nil {
-		stderr.Error(err.Error())
+		stderr.Errorf(err.Error())
 
 		return err
 	}
 
-	err = box.Lookup("template_"+com.String()+"_test").Execute(fd, comArgs)
-	if err != nil {
+	if err := tpl.Execute(fd, model); err != nil {
 		stderr.Errorf(err.Error())
 
 		return err
@@ -225,6 +494,41 @@ func (_ *_Component) newComponent(com ComponentType, name string, args ...string
 	return nil
 }
 
+// loadTemplate resolves a template by key, preferring a project-local
+// override under .gogo/templates/ over the compiled-in template box.
+func (_ *_Component) loadTemplate(projectRoot, key string) (*template.Template, error) {
+	overridePath := path.Join(projectRoot, templatesOverrideDir, key)
+
+	if raw, err := ioutil.ReadFile(overridePath); err == nil {
+		return template.New(key).Parse(string(raw))
+	}
+
+	tpl := box.Lookup(key)
+	if tpl == nil {
+		return nil, fmt.Errorf("gogo: no template registered for %q; add one under %s or register a default via RegisterDefaultTemplate", key, templatesOverrideDir)
+	}
+
+	return tpl, nil
+}
+
+// resolveFlags reads every flag set on ctx into a plain map, the same
+// way pkgs/config.CommandLineProvider resolves cli flags into config
+// values, so WithFlags(...) can expose a component type's generate flags
+// to its templates as .Flags.
+func (_ *_Component) resolveFlags(ctx *cli.Context) map[string]interface{} {
+	flags := map[string]interface{}{}
+
+	for _, name := range ctx.FlagNames() {
+		if !ctx.IsSet(name) {
+			continue
+		}
+
+		flags[name] = ctx.String(name)
+	}
+
+	return flags
+}
+
 func (_ *_Component) toCamelCase(name string) (capitalName string) {
 	names := named.ToCamelCase(name)
 	for i, tmpname := range names {
@@ -239,6 +543,19 @@ func (_ *_Component) toCamelCase(name string) (capitalName string) {
 	return strings.Join(names, "")
 }
 
+// toExportedNames capitalizes each of names for use as a Go identifier,
+// e.g. turning the controller template's lowercase "-actions" flag
+// values ("index", "create", ...) into method names ("Index", "Create",
+// ...) via .Fields.Actions.
+func (_ *_Component) toExportedNames(names []string) []string {
+	exported := make([]string, len(names))
+	for i, name := range names {
+		exported[i] = strings.Title(name)
+	}
+
+	return exported
+}
+
 func (_ *_Component) toFilename(name string) (filename string) {
 	filenames := []string{}
 
@@ -252,4 +569,4 @@ func (_ *_Component) toFilename(name string) (filename string) {
 	}
 
 	return strings.Join(filenames, "_") + ".go"
-}
\ No newline at end of file
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+// Test_DefaultTemplates_Registered asserts every built-in component type
+// has a compiled-in default template backing each of its Templates
+// keys, so `gogo generate controller|middleware|model NAME` doesn't
+// fail with "no template registered" the moment a project has no
+// .gogo/templates override -- the regression loadTemplate silently
+// shipped with when the pluggable registry replaced the old box.
+func Test_DefaultTemplates_Registered(t *testing.T) {
+	it := assert.New(t)
+
+	for _, name := range []string{ComTypeController, ComTypeMiddleware, ComTypeModel, "grpc", "gateway"} {
+		ct, ok := lookupComponentType(name)
+		if !it.True(ok, name) {
+			continue
+		}
+
+		for role, key := range ct.Templates {
+			it.NotNil(box.Lookup(key), "%s/%s (%s) must have a default template", name, role, key)
+		}
+	}
+}
+
+// Test_loadTemplate_controller renders the controller's default source
+// and test templates end-to-end against a real ComTemplateModel, the
+// way newComponent does, proving .Fields.Actions/.Args/.Imports are all
+// honored.
+func Test_loadTemplate_controller(t *testing.T) {
+	it := assert.New(t)
+
+	ct, ok := lookupComponentType(ComTypeController)
+	it.True(ok)
+
+	model := &ComTemplateModel{
+		Name: "Profile",
+		Args: []string{"index", "show"},
+	}
+	WithFields(map[string]interface{}{
+		"Actions": Component.toExportedNames(model.Args),
+	})(model)
+	WithImports("github.com/dolab/gogo/app/validators")(model)
+
+	source, err := Component.loadTemplate("", ct.Templates["source"])
+	it.Nil(err)
+
+	var sourceOut bytes.Buffer
+	it.Nil(source.Execute(&sourceOut, model))
+	it.Contains(sourceOut.String(), "func (_ *ProfileController) Index(ctx *gogo.Context)")
+	it.Contains(sourceOut.String(), "func (_ *ProfileController) Show(ctx *gogo.Context)")
+	it.Contains(sourceOut.String(), `"github.com/dolab/gogo/app/validators"`)
+
+	test, err := Component.loadTemplate("", ct.Templates["test"])
+	it.Nil(err)
+
+	var testOut bytes.Buffer
+	it.Nil(test.Execute(&testOut, model))
+	it.Contains(testOut.String(), "Test_ProfileController")
+}
+
+// Test_loadTemplate_grpc renders the grpc type's default .proto and stub
+// templates end-to-end, proving NewGRPC's .Fields.Package wiring and the
+// one-rpc-per-method render both work against the compiled-in defaults.
+func Test_loadTemplate_grpc(t *testing.T) {
+	it := assert.New(t)
+
+	ct, ok := lookupComponentType("grpc")
+	it.True(ok)
+
+	model := &ComTemplateModel{
+		Name: "Profile",
+		Args: []string{"Get", "List"},
+		Fields: map[string]interface{}{
+			"Package": "profile",
+		},
+	}
+
+	proto, err := Component.loadTemplate("", ct.Templates["proto"])
+	it.Nil(err)
+
+	var protoOut bytes.Buffer
+	it.Nil(proto.Execute(&protoOut, model))
+	it.Contains(protoOut.String(), "package profile;")
+	it.Contains(protoOut.String(), "service Profile {")
+	it.Contains(protoOut.String(), "rpc Get (Empty) returns (Empty);")
+	it.Contains(protoOut.String(), "rpc List (Empty) returns (Empty);")
+
+	stub, err := Component.loadTemplate("", ct.Templates["stub"])
+	it.Nil(err)
+
+	var stubOut bytes.Buffer
+	it.Nil(stub.Execute(&stubOut, model))
+	it.Contains(stubOut.String(), "type ProfileGRPC struct")
+	it.Contains(stubOut.String(), "func (_ *ProfileGRPC) Get(ctx context.Context, req *Empty) (*Empty, error)")
+	it.Contains(stubOut.String(), "func (_ *ProfileGRPC) List(ctx context.Context, req *Empty) (*Empty, error)")
+}
@@ -0,0 +1,40 @@
+package commands
+
+func init() {
+	RegisterComponentType("gateway", []string{"app", "gateways"}, map[string]string{
+		"source": "template_gateway",
+		"test":   "template_gateway_test",
+	}, nil)
+
+	RegisterDefaultTemplate("template_gateway", gatewaySourceTemplate)
+	RegisterDefaultTemplate("template_gateway_test", gatewayTestTemplate)
+}
+
+const gatewaySourceTemplate = `package gateways
+
+import (
+	"github.com/dolab/gogo/gateway"
+)
+
+// New{{.Name}}Service returns a gateway.Service proxying to the upstreams
+// held in registry, resolved by trying resolvers in order.
+func New{{.Name}}Service(registry *gateway.RouterRegistry, resolvers ...gateway.Resolver) *gateway.Service {
+	return gateway.NewService(registry, resolvers...)
+}
+`
+
+const gatewayTestTemplate = `package gateways
+
+import (
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_New{{.Name}}Service(t *testing.T) {
+	it := assert.New(t)
+
+	service := New{{.Name}}Service(nil)
+	it.NotNil(service)
+}
+`
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/golib/cli"
+)
+
+func init() {
+	RegisterComponentType("grpc", []string{"app", "controllers"}, map[string]string{
+		"proto": "template_grpc_proto",
+		"stub":  "template_grpc_stub",
+	}, []cli.Flag{
+		cli.StringSliceFlag{
+			Name:  "methods",
+			Usage: "specify unary rpc methods to generate, e.g. -methods=Get,List",
+		},
+	})
+
+	customComponentActions["grpc"] = Component.NewGRPC()
+
+	RegisterDefaultTemplate("template_grpc_proto", grpcProtoTemplate)
+	RegisterDefaultTemplate("template_grpc_stub", grpcStubTemplate)
+}
+
+// grpcProtoTemplate and grpcStubTemplate are the compiled-in defaults
+// for "grpc": a .proto definition with one unary rpc per requested
+// method, and the Go controller stub compiled against it.
+// .Fields.Package holds the lowercased proto package name (see NewGRPC).
+const grpcProtoTemplate = `syntax = "proto3";
+
+package {{.Fields.Package}};
+
+option go_package = ".;controllers";
+
+service {{.Name}} {
+{{range .Args}}	rpc {{.}} (Empty) returns (Empty);
+{{end}}}
+
+message Empty {}
+`
+
+const grpcStubTemplate = `package controllers
+
+import (
+	"context"
+)
+
+// {{.Name}}GRPC implements the {{.Name}}Server interface protoc-gen-go-grpc
+// generates from {{.Fields.Package}}.proto.
+type {{.Name}}GRPC struct {
+	Unimplemented{{.Name}}Server
+}
+{{range .Args}}
+// {{.}} implements {{$.Name}}Server.
+func (_ *{{$.Name}}GRPC) {{.}}(ctx context.Context, req *Empty) (*Empty, error) {
+	return &Empty{}, nil
+}
+{{end}}`
+
+// NewGRPC scaffolds a .proto definition for name, compiles it by running
+// protoc with --go_out and --go-grpc_out (no plugin path is passed, so
+// protoc uses the protoc-gen-go and protoc-gen-go-grpc found on PATH)
+// and emits a xxx_grpc.go controller stub next to the existing xxx.go
+// HTTP controller.
+func (_ *_Component) NewGRPC() cli.ActionFunc {
+	return func(ctx *cli.Context) error {
+		name := path.Clean(ctx.Args().First())
+
+		ct, ok := lookupComponentType("grpc")
+		if !ok {
+			return ErrComponentType
+		}
+
+		root, err := os.Getwd()
+		if err != nil {
+			stderr.Error(err.Error())
+
+			return err
+		}
+
+		comRoot := ct.Root(root)
+		if !strings.Contains(comRoot, "/gogo/") {
+			return ErrInvalidRoot
+		}
+
+		comArgs := &ComTemplateModel{
+			Name:  Component.toCamelCase(name),
+			Args:  ctx.StringSlice("methods"),
+			Flags: Component.resolveFlags(ctx),
+			Fields: map[string]interface{}{
+				"Package": strings.ToLower(name),
+			},
+		}
+
+		protoFilename := path.Join(comRoot, name+".proto")
+
+		if err := Component.renderTemplate(ct.projectRoot(root), ct.Templates["proto"], protoFilename, comArgs); err != nil {
+			return err
+		}
+
+		// protoc --go_out=. --go-grpc_out=. name.proto
+		cmd := exec.Command("protoc", "--go_out=.", "--go-grpc_out=.", name+".proto")
+		cmd.Dir = comRoot
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			stderr.Error(err.Error())
+
+			return err
+		}
+
+		stubFilename := path.Join(comRoot, Component.toFilename(name+"_grpc"))
+
+		return Component.renderTemplate(ct.projectRoot(root), ct.Templates["stub"], stubFilename, comArgs)
+	}
+}
@@ -0,0 +1,12 @@
+package gateway
+
+import "errors"
+
+var (
+	// ErrNoRoute is returned by a Resolver when no upstream matches the request.
+	ErrNoRoute = errors.New("gateway: no matching upstream")
+
+	// ErrUpstreamNotRegistered is returned when a Resolver matched a
+	// name the RouterRegistry doesn't know about.
+	ErrUpstreamNotRegistered = errors.New("gateway: upstream not registered")
+)
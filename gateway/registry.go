@@ -0,0 +1,96 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// RouterRegistry tracks the address of every upstream a Resolver may
+// send a request to. It can be populated statically from config at
+// startup, or dynamically at runtime via AdminHandler.
+type RouterRegistry struct {
+	mux       sync.RWMutex
+	upstreams map[string]string
+}
+
+// NewRouterRegistry returns a RouterRegistry pre-populated with
+// upstreams (name => address), which may be empty.
+func NewRouterRegistry(upstreams map[string]string) *RouterRegistry {
+	rr := &RouterRegistry{
+		upstreams: map[string]string{},
+	}
+
+	for name, address := range upstreams {
+		rr.upstreams[name] = address
+	}
+
+	return rr
+}
+
+// Register associates name with address, overwriting any previous value.
+func (rr *RouterRegistry) Register(name, address string) {
+	rr.mux.Lock()
+	defer rr.mux.Unlock()
+
+	rr.upstreams[name] = address
+}
+
+// Deregister removes name from the registry.
+func (rr *RouterRegistry) Deregister(name string) {
+	rr.mux.Lock()
+	defer rr.mux.Unlock()
+
+	delete(rr.upstreams, name)
+}
+
+// Address returns the registered address for name, if any.
+func (rr *RouterRegistry) Address(name string) (string, bool) {
+	rr.mux.RLock()
+	defer rr.mux.RUnlock()
+
+	address, ok := rr.upstreams[name]
+
+	return address, ok
+}
+
+// AdminHandler exposes the registry over HTTP for dynamic updates:
+//
+//	GET    /upstreams          list every registered upstream
+//	PUT    /upstreams/{name}   register {"address": "host:port"}
+//	DELETE /upstreams/{name}   deregister
+func (rr *RouterRegistry) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/upstreams/")
+
+		switch r.Method {
+		case http.MethodGet:
+			rr.mux.RLock()
+			defer rr.mux.RUnlock()
+
+			json.NewEncoder(w).Encode(rr.upstreams)
+
+		case http.MethodPut:
+			var body struct {
+				Address string `json:"address"`
+			}
+
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+
+				return
+			}
+
+			rr.Register(name, body.Address)
+			w.WriteHeader(http.StatusNoContent)
+
+		case http.MethodDelete:
+			rr.Deregister(name)
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
@@ -0,0 +1,89 @@
+// Package gateway lets a gogo app act as a reverse proxy in front of
+// other gogo/micro services, analogous to go-micro's path/host/vpath
+// resolvers.
+package gateway
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Endpoint describes the upstream a Resolver chose for a request.
+type Endpoint struct {
+	// Name identifies the upstream in a RouterRegistry, e.g. "users".
+	Name string
+
+	// Address, when set, is used directly instead of looking Name up
+	// in the RouterRegistry (e.g. a Resolver that already knows the
+	// upstream's host:port).
+	Address string
+
+	// Version is the API version the request targeted, if any (e.g.
+	// "v1" for VersionedPathResolver), empty otherwise.
+	Version string
+}
+
+// Resolver picks the upstream Endpoint a request should be proxied to.
+type Resolver interface {
+	Resolve(r *http.Request) (*Endpoint, error)
+}
+
+// PathResolver strips a leading path prefix and forwards whatever
+// matched it to a single named upstream, e.g.
+// &PathResolver{Prefix: "/users/", Upstream: "users"} routes
+// "/users/42" to upstream "users".
+type PathResolver struct {
+	Prefix   string
+	Upstream string
+}
+
+func (pr *PathResolver) Resolve(r *http.Request) (*Endpoint, error) {
+	if !strings.HasPrefix(r.URL.Path, pr.Prefix) {
+		return nil, ErrNoRoute
+	}
+
+	r.URL.Path = "/" + strings.TrimPrefix(r.URL.Path, pr.Prefix)
+
+	return &Endpoint{
+		Name: pr.Upstream,
+	}, nil
+}
+
+// HostResolver chooses an upstream by the request's Host header.
+type HostResolver struct {
+	// Upstreams maps a Host header value to an upstream name.
+	Upstreams map[string]string
+}
+
+func (hr *HostResolver) Resolve(r *http.Request) (*Endpoint, error) {
+	name, ok := hr.Upstreams[r.Host]
+	if !ok {
+		return nil, ErrNoRoute
+	}
+
+	return &Endpoint{
+		Name: name,
+	}, nil
+}
+
+// VersionedPathResolver maps "/v1/users/..." to upstream "users",
+// version "v1", forwarding "/..." to the upstream.
+type VersionedPathResolver struct{}
+
+func (vr *VersionedPathResolver) Resolve(r *http.Request) (*Endpoint, error) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return nil, ErrNoRoute
+	}
+
+	remainder := ""
+	if len(parts) == 3 {
+		remainder = parts[2]
+	}
+	r.URL.Path = "/" + remainder
+
+	return &Endpoint{
+		Name:    parts[1],
+		Version: parts[0],
+	}, nil
+}
@@ -0,0 +1,87 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+func Test_PathResolver(t *testing.T) {
+	it := assert.New(t)
+
+	resolver := &PathResolver{Prefix: "/users/", Upstream: "users"}
+
+	r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	endpoint, err := resolver.Resolve(r)
+	if it.Nil(err) {
+		it.Equal("users", endpoint.Name)
+		it.Equal("/42", r.URL.Path)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/orders/42", nil)
+	_, err = resolver.Resolve(r)
+	it.Equal(ErrNoRoute, err)
+}
+
+func Test_HostResolver(t *testing.T) {
+	it := assert.New(t)
+
+	resolver := &HostResolver{
+		Upstreams: map[string]string{
+			"users.example.com": "users",
+		},
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Host = "users.example.com"
+
+	endpoint, err := resolver.Resolve(r)
+	if it.Nil(err) {
+		it.Equal("users", endpoint.Name)
+	}
+
+	r.Host = "orders.example.com"
+	_, err = resolver.Resolve(r)
+	it.Equal(ErrNoRoute, err)
+}
+
+func Test_VersionedPathResolver(t *testing.T) {
+	it := assert.New(t)
+
+	resolver := &VersionedPathResolver{}
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/users/42", nil)
+	endpoint, err := resolver.Resolve(r)
+	if it.Nil(err) {
+		it.Equal("users", endpoint.Name)
+		it.Equal("v1", endpoint.Version)
+		it.Equal("/42", r.URL.Path)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/v1", nil)
+	_, err = resolver.Resolve(r)
+	it.Equal(ErrNoRoute, err)
+}
+
+func Test_RouterRegistry(t *testing.T) {
+	it := assert.New(t)
+
+	registry := NewRouterRegistry(map[string]string{
+		"users": "127.0.0.1:9001",
+	})
+
+	address, ok := registry.Address("users")
+	it.True(ok)
+	it.Equal("127.0.0.1:9001", address)
+
+	registry.Register("orders", "127.0.0.1:9002")
+	address, ok = registry.Address("orders")
+	it.True(ok)
+	it.Equal("127.0.0.1:9002", address)
+
+	registry.Deregister("orders")
+	_, ok = registry.Address("orders")
+	it.False(ok)
+}
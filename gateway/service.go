@@ -0,0 +1,111 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/dolab/gogo"
+)
+
+// Service proxies requests to upstream gogo/micro services, resolved by
+// trying Resolvers in order until one matches. It implements the same
+// contract gogo.AppServer.NewService expects of any service
+// (Init/Middlewares/Resources), so it participates in the normal
+// Middlewares()/RequestReceivedHooks() lifecycle like any other service.
+type Service struct {
+	Resolvers []Resolver
+	Registry  *RouterRegistry
+
+	group gogo.Grouper
+}
+
+// NewService returns a gateway Service that resolves requests against
+// resolvers (tried in order) and forwards them to addresses held in
+// registry.
+func NewService(registry *RouterRegistry, resolvers ...Resolver) *Service {
+	return &Service{
+		Resolvers: resolvers,
+		Registry:  registry,
+	}
+}
+
+// Init implements gogo's Service contract.
+func (svc *Service) Init(config gogo.Configer, group gogo.Grouper) {
+	svc.group = group
+}
+
+// Middlewares implements gogo's Service contract. The gateway forwards
+// as-is and has no middlewares of its own beyond the catch-all proxy
+// registered in Resources.
+func (svc *Service) Middlewares() {}
+
+// Resources registers the catch-all proxy route.
+func (svc *Service) Resources() {
+	for _, method := range []string{
+		http.MethodGet, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodHead,
+	} {
+		svc.group.Handle(method, "/*path", svc.proxy)
+	}
+}
+
+// proxy resolves ctx's upstream and forwards the request to it,
+// propagating the request id set by AppServer's logger pool.
+func (svc *Service) proxy(ctx *gogo.Context) {
+	address, err := svc.resolve(ctx.Request)
+	if err != nil {
+		ctx.SetStatus(http.StatusBadGateway)
+		ctx.Text(err.Error())
+
+		return
+	}
+
+	logger := gogo.NewRequestLogger(ctx.Request)
+	ctx.Request.Header.Set("X-Request-Id", logger.RequestID())
+
+	proxy := httputil.NewSingleHostReverseProxy(&url.URL{
+		Scheme: "http",
+		Host:   address,
+	})
+	proxy.ServeHTTP(ctx.Writer, ctx.Request)
+}
+
+// resolve tries svc.Resolvers in order against a clone of r, so a
+// resolver that matches but whose Endpoint.Name misses the Registry
+// (e.g. a stale or unregistered upstream) falls through to the next
+// resolver against the original, unmutated request rather than the
+// path/host a prior resolver already rewrote as a side effect of
+// matching. It returns ErrNoRoute if no resolver matched at all, or
+// ErrUpstreamNotRegistered if at least one did but every such match
+// missed the Registry, so proxy's 502 can tell the two apart.
+func (svc *Service) resolve(r *http.Request) (string, error) {
+	err := error(ErrNoRoute)
+
+	for _, resolver := range svc.Resolvers {
+		attempt := r.Clone(r.Context())
+
+		endpoint, resolveErr := resolver.Resolve(attempt)
+		if resolveErr != nil {
+			continue
+		}
+
+		address := endpoint.Address
+		if address == "" {
+			var ok bool
+
+			address, ok = svc.Registry.Address(endpoint.Name)
+			if !ok {
+				err = ErrUpstreamNotRegistered
+
+				continue
+			}
+		}
+
+		r.URL = attempt.URL
+
+		return address, nil
+	}
+
+	return "", err
+}
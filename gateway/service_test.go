@@ -0,0 +1,64 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golib/assert"
+)
+
+// Test_Service_resolve asserts that a resolver which matches but whose
+// Endpoint.Name misses the Registry falls through to the next resolver
+// against the original request, rather than the path the first resolver
+// already mutated as a side effect of matching.
+func Test_Service_resolve(t *testing.T) {
+	it := assert.New(t)
+
+	registry := NewRouterRegistry(map[string]string{
+		"orders": "127.0.0.1:9002",
+	})
+
+	svc := NewService(registry,
+		&PathResolver{Prefix: "/users/", Upstream: "users"}, // matches, but "users" is never registered
+		&PathResolver{Prefix: "/users/", Upstream: "orders"},
+	)
+
+	r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+
+	address, err := svc.resolve(r)
+	it.Nil(err)
+	it.Equal("127.0.0.1:9002", address)
+	it.Equal("/42", r.URL.Path, "the committed resolver's rewrite should apply to the real request")
+}
+
+// Test_Service_resolve_noRoute asserts resolve returns ErrNoRoute when no
+// resolver matches at all.
+func Test_Service_resolve_noRoute(t *testing.T) {
+	it := assert.New(t)
+
+	registry := NewRouterRegistry(nil)
+	svc := NewService(registry, &PathResolver{Prefix: "/users/", Upstream: "users"})
+
+	r := httptest.NewRequest(http.MethodGet, "/orders/42", nil)
+
+	_, err := svc.resolve(r)
+	it.Equal(ErrNoRoute, err)
+	it.Equal("/orders/42", r.URL.Path, "a non-matching resolver must not mutate the request")
+}
+
+// Test_Service_resolve_upstreamNotRegistered asserts resolve returns
+// ErrUpstreamNotRegistered (rather than the generic ErrNoRoute) when a
+// resolver matched but its Endpoint.Name isn't in the Registry, so
+// proxy's 502 can tell a routing miss apart from a registry miss.
+func Test_Service_resolve_upstreamNotRegistered(t *testing.T) {
+	it := assert.New(t)
+
+	registry := NewRouterRegistry(nil)
+	svc := NewService(registry, &PathResolver{Prefix: "/users/", Upstream: "users"})
+
+	r := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+
+	_, err := svc.resolve(r)
+	it.Equal(ErrUpstreamNotRegistered, err)
+}
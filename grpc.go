@@ -0,0 +1,53 @@
+package gogo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// GRPCer is implemented by services that expose gRPC methods alongside
+// their HTTP routes. NewService type-asserts for it the same way it
+// discovers RequestReceivedHooks/ResponseReadyHooks, registering every
+// implementation against the shared *grpc.Server before Serve is
+// called.
+type GRPCer interface {
+	RegisterGRPC(s *grpc.Server)
+}
+
+// requestIDMetadataKey is the gRPC metadata key propagated for a
+// request id, mirroring the HTTP X-Request-Id header.
+const requestIDMetadataKey = "x-request-id"
+
+// NewGRPCUnaryInterceptor returns a grpc.UnaryServerInterceptor that
+// pulls a request-scoped Logger out of logger's pool the same way
+// AppServer.loggerNew/loggerReuse do for HTTP requests, so gRPC handlers
+// share the same tagged, reused logger.
+func NewGRPCUnaryInterceptor(logger *AppLogger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		requestID := grpcRequestID(ctx)
+
+		alog := logger.New(requestID)
+		defer logger.Reuse(alog)
+
+		return handler(context.WithValue(ctx, ctxLoggerKey, alog), req)
+	}
+}
+
+// grpcRequestID returns the request id propagated via metadata, falling
+// back to a freshly generated one for calls that didn't set it.
+func grpcRequestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadataKey); len(values) > 0 {
+			return values[0]
+		}
+	}
+
+	buf := make([]byte, 16)
+	rand.Read(buf)
+
+	return hex.EncodeToString(buf)
+}
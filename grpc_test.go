@@ -0,0 +1,27 @@
+package gogo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golib/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func Test_NewGRPCUnaryInterceptor(t *testing.T) {
+	it := assert.New(t)
+
+	logger := NewAppLogger("nil", "")
+	interceptor := NewGRPCUnaryInterceptor(logger)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestIDMetadataKey, "di-tseuqer-x"))
+
+	_, err := interceptor(ctx, nil, nil, func(ctx context.Context, req interface{}) (interface{}, error) {
+		alog, ok := ctx.Value(ctxLoggerKey).(Logger)
+		it.True(ok)
+		it.Equal("di-tseuqer-x", alog.RequestID())
+
+		return nil, nil
+	})
+	it.Nil(err)
+}
@@ -10,6 +10,27 @@ import (
 	"github.com/dolab/logger"
 )
 
+// ctxKey namespaces values gogo stores on a request's context, keeping
+// them out of collision with keys other packages put on the same
+// context.
+type ctxKey int
+
+// ctxLoggerKey is the context key AppServer and NewGRPCUnaryInterceptor
+// store the request-scoped Logger under.
+const ctxLoggerKey ctxKey = iota
+
+// Logger is the interface request handlers and gRPC interceptors depend
+// on instead of the concrete pooled *AppLogger, so tests can assert
+// against it directly.
+type Logger interface {
+	// RequestID returns the request id this Logger is tagged with.
+	RequestID() string
+
+	// New returns a Logger tagged with requestID, sharing this one's
+	// writer.
+	New(requestID string) Logger
+}
+
 // AppLogger defines log component of gogo, it implements Logger interface
 // with pool support
 type AppLogger struct {
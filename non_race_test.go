@@ -9,11 +9,14 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/dolab/gogo/pkgs/config"
 	"github.com/dolab/gogo/pkgs/hooks"
 	"github.com/dolab/httptesting"
 	"github.com/golib/assert"
@@ -281,6 +284,60 @@ func (svc *testService) ResponseAlwaysHooks() []hooks.NamedHook {
 	}
 }
 
+// testConfigService implements configChangedHooker, the minimal shape
+// Test_Server_NewServiceWithConfigChangedHook needs to prove a hook
+// registered via NewService actually fires on a real config change,
+// mirroring how testService exercises the request lifecycle hooks.
+type testConfigService struct {
+	changed chan interface{}
+}
+
+func (svc *testConfigService) Init(config Configer, group Grouper) {}
+func (svc *testConfigService) Middlewares()                        {}
+func (svc *testConfigService) Resources()                          {}
+
+func (svc *testConfigService) ConfigChangedHooks() []hooks.ConfigChangedHook {
+	return []hooks.ConfigChangedHook{
+		{
+			Name: "app_name_changed@testing",
+			Key:  "app.name",
+			Apply: func(key string, newValue, oldValue interface{}) {
+				svc.changed <- newValue
+			},
+		},
+	}
+}
+
+// Test_Server_NewServiceWithConfigChangedHook asserts a configChangedHook
+// registered via NewService actually fires when its watched key changes
+// on disk, not just that config.Watch was called without error.
+func Test_Server_NewServiceWithConfigChangedHook(t *testing.T) {
+	it := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "gogo-server-config")
+	it.Nil(err)
+	defer os.RemoveAll(dir)
+
+	filePath := filepath.Join(dir, "application.json")
+	it.Nil(ioutil.WriteFile(filePath, []byte(`{"network": "tcp", "address": "127.0.0.1:0", "app": {"name": "gogo"}}`), 0644))
+
+	cfg, err := config.NewLoader().WithPaths(dir).Load()
+	it.Nil(err)
+
+	server := NewAppServer(cfg, NewAppLogger("nil", ""))
+	service := &testConfigService{changed: make(chan interface{}, 1)}
+	server.NewService(service)
+
+	it.Nil(ioutil.WriteFile(filePath, []byte(`{"network": "tcp", "address": "127.0.0.1:0", "app": {"name": "gogo-reloaded"}}`), 0644))
+
+	select {
+	case newValue := <-service.changed:
+		it.Equal("gogo-reloaded", newValue)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for config change notification")
+	}
+}
+
 func Test_Server_NewService(t *testing.T) {
 	it := assert.New(t)
 	service := &testService{}
@@ -0,0 +1,295 @@
+// Package config composes layered configuration providers (file,
+// environment, command-line) into a single, optionally hot-reloadable
+// Configer, used to extend the Configer passed to gogo.NewAppServer.
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/golib/cli"
+)
+
+// Provider supplies configuration values from a single source. Loader
+// composes multiple Providers in priority order, later providers
+// overriding earlier ones for keys they both define.
+type Provider interface {
+	// Name identifies the provider for diagnostics, e.g. "file:config/application.json".
+	Name() string
+
+	// Load returns every key/value pair this provider currently supplies.
+	Load() (map[string]interface{}, error)
+}
+
+// FileProvider loads a flat key/value map from a JSON file, the same
+// format the existing Configer reads on startup.
+type FileProvider struct {
+	path string
+}
+
+// NewFileProvider returns a Provider backed by the JSON file at path.
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{path: path}
+}
+
+func (p *FileProvider) Name() string {
+	return "file:" + p.path
+}
+
+func (p *FileProvider) Load() (map[string]interface{}, error) {
+	raw, err := ioutil.ReadFile(p.path)
+	if err != nil {
+		return nil, err
+	}
+
+	nested := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &nested); err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+	flatten("", nested, values)
+
+	return values, nil
+}
+
+// flatten walks a decoded JSON object into dotted keys (e.g.
+// {"app":{"port":8080}} becomes {"app.port": 8080}), so a nested
+// application.json merges on the same keys EnvProvider/
+// CommandLineProvider synthesize.
+func flatten(prefix string, nested map[string]interface{}, values map[string]interface{}) {
+	for key, value := range nested {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if child, ok := value.(map[string]interface{}); ok {
+			flatten(key, child, values)
+
+			continue
+		}
+
+		values[key] = value
+	}
+}
+
+// EnvProvider loads values from environment variables sharing prefix,
+// e.g. with prefix "GOGO_" the variable GOGO_APP_NAME maps to key
+// "app.name".
+type EnvProvider struct {
+	prefix string
+}
+
+// NewEnvProvider returns a Provider backed by os.Environ, limited to
+// variables starting with prefix.
+func NewEnvProvider(prefix string) *EnvProvider {
+	return &EnvProvider{prefix: prefix}
+}
+
+func (p *EnvProvider) Name() string {
+	return "env:" + p.prefix
+}
+
+func (p *EnvProvider) Load() (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	for _, kv := range os.Environ() {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], p.prefix) {
+			continue
+		}
+
+		name, value := parts[0], parts[1]
+
+		key := strings.TrimPrefix(name, p.prefix)
+		key = strings.ToLower(strings.Replace(key, "_", ".", -1))
+
+		values[key] = value
+	}
+
+	return values, nil
+}
+
+// CommandLineProvider loads values from flags already parsed onto ctx,
+// so commands registered against the same github.com/golib/cli context
+// as the commands package can override config inline, e.g.
+// `gogo server -app.port=8080`.
+type CommandLineProvider struct {
+	ctx *cli.Context
+}
+
+// NewCommandLineProvider returns a Provider backed by ctx's set flags.
+func NewCommandLineProvider(ctx *cli.Context) *CommandLineProvider {
+	return &CommandLineProvider{ctx: ctx}
+}
+
+func (p *CommandLineProvider) Name() string {
+	return "cli"
+}
+
+func (p *CommandLineProvider) Load() (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	for _, name := range p.ctx.FlagNames() {
+		if !p.ctx.IsSet(name) {
+			continue
+		}
+
+		values[name] = p.ctx.String(name)
+	}
+
+	return values, nil
+}
+
+// Loader composes Providers in priority order (later providers win) and
+// produces a merged Configer. Use WithPaths to register directories
+// searched for the default "application.json" file provider, and
+// WithProvider to layer in others, e.g.:
+//
+//	config.NewLoader().
+//		WithPaths(dirs...).
+//		WithProvider(config.NewCommandLineProvider(ctx)).
+//		WithProvider(config.NewEnvProvider("GOGO_")).
+//		Load()
+type Loader struct {
+	paths     []string
+	providers []Provider
+}
+
+// NewLoader returns an empty Loader.
+func NewLoader() *Loader {
+	return &Loader{}
+}
+
+// WithPaths registers directories to search for "application.json",
+// loaded (in order) before any provider added via WithProvider.
+func (l *Loader) WithPaths(dirs ...string) *Loader {
+	l.paths = append(l.paths, dirs...)
+
+	return l
+}
+
+// WithProvider appends a Provider to the merge order.
+func (l *Loader) WithProvider(provider Provider) *Loader {
+	l.providers = append(l.providers, provider)
+
+	return l
+}
+
+// Load resolves every registered path into a FileProvider, runs all
+// providers in order and merges their values, later providers
+// overriding earlier ones for shared keys. A missing config file is not
+// an error, so a project without application.json can still be
+// configured entirely from the environment or command-line flags.
+func (l *Loader) Load() (*Config, error) {
+	filePaths := make([]string, 0, len(l.paths))
+	providers := make([]Provider, 0, len(l.paths)+len(l.providers))
+
+	for _, dir := range l.paths {
+		filePath := filepath.Join(dir, "application.json")
+
+		filePaths = append(filePaths, filePath)
+		providers = append(providers, NewFileProvider(filePath))
+	}
+	providers = append(providers, l.providers...)
+
+	values := map[string]interface{}{}
+	for _, provider := range providers {
+		loaded, err := provider.Load()
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		for key, value := range loaded {
+			values[key] = value
+		}
+	}
+
+	return &Config{
+		filePaths: filePaths,
+		providers: l.providers,
+		values:    values,
+	}, nil
+}
+
+// Config is a merged, read-only view over one or more Providers, with
+// optional hot-reload via Watch.
+type Config struct {
+	filePaths []string
+	providers []Provider
+
+	mux    sync.RWMutex
+	values map[string]interface{}
+
+	watchMux sync.Mutex
+	watcher  *watcher
+}
+
+// Get returns the merged value for key, or nil if no provider supplied it.
+func (c *Config) Get(key string) interface{} {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	return c.values[key]
+}
+
+// Watch registers fn to run whenever key's merged value changes after a
+// tracked config file is rewritten on disk. The underlying fsnotify
+// watcher is started lazily on the first call to Watch.
+func (c *Config) Watch(key string, fn func(new, old interface{})) error {
+	c.watchMux.Lock()
+	defer c.watchMux.Unlock()
+
+	if c.watcher == nil {
+		w, err := newWatcher(c)
+		if err != nil {
+			return err
+		}
+
+		c.watcher = w
+	}
+
+	c.watcher.subscribe(key, fn)
+
+	return nil
+}
+
+func (c *Config) reload() (map[string]interface{}, map[string]interface{}, error) {
+	values := map[string]interface{}{}
+
+	providers := make([]Provider, 0, len(c.filePaths)+len(c.providers))
+	for _, filePath := range c.filePaths {
+		providers = append(providers, NewFileProvider(filePath))
+	}
+	providers = append(providers, c.providers...)
+
+	for _, provider := range providers {
+		loaded, err := provider.Load()
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			return nil, nil, err
+		}
+
+		for key, value := range loaded {
+			values[key] = value
+		}
+	}
+
+	c.mux.Lock()
+	old := c.values
+	c.values = values
+	c.mux.Unlock()
+
+	return values, old, nil
+}
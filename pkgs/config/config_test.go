@@ -0,0 +1,91 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+)
+
+func Test_Loader_Load(t *testing.T) {
+	it := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "gogo-config")
+	it.Nil(err)
+	defer os.RemoveAll(dir)
+
+	it.Nil(ioutil.WriteFile(filepath.Join(dir, "application.json"), []byte(`{"app": {"name": "gogo", "port": 80}}`), 0644))
+	os.Setenv("GOGO_APP_PORT", "8080")
+	defer os.Unsetenv("GOGO_APP_PORT")
+
+	config, err := NewLoader().
+		WithPaths(dir).
+		WithProvider(NewEnvProvider("GOGO_")).
+		Load()
+	it.Nil(err)
+
+	it.Equal("gogo", config.Get("app.name"))
+	it.Equal("8080", config.Get("app.port"), "env provider should override the nested file value")
+}
+
+func Test_Config_Watch(t *testing.T) {
+	it := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "gogo-config")
+	it.Nil(err)
+	defer os.RemoveAll(dir)
+
+	filePath := filepath.Join(dir, "application.json")
+	it.Nil(ioutil.WriteFile(filePath, []byte(`{"app": {"name": "gogo"}}`), 0644))
+
+	config, err := NewLoader().WithPaths(dir).Load()
+	it.Nil(err)
+
+	changed := make(chan interface{}, 1)
+	it.Nil(config.Watch("app.name", func(newValue, oldValue interface{}) {
+		changed <- newValue
+	}))
+
+	it.Nil(ioutil.WriteFile(filePath, []byte(`{"app": {"name": "gogo-reloaded"}}`), 0644))
+
+	select {
+	case newValue := <-changed:
+		it.Equal("gogo-reloaded", newValue)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for config change notification")
+	}
+}
+
+// Test_Config_WatchSlice guards against comparing reloaded values with
+// "==", which panics ("comparing uncomparable type []interface {}") the
+// moment a watched key's JSON value is an array.
+func Test_Config_WatchSlice(t *testing.T) {
+	it := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "gogo-config")
+	it.Nil(err)
+	defer os.RemoveAll(dir)
+
+	filePath := filepath.Join(dir, "application.json")
+	it.Nil(ioutil.WriteFile(filePath, []byte(`{"app": {"tags": ["a", "b"]}}`), 0644))
+
+	config, err := NewLoader().WithPaths(dir).Load()
+	it.Nil(err)
+
+	changed := make(chan interface{}, 1)
+	it.Nil(config.Watch("app.tags", func(newValue, oldValue interface{}) {
+		changed <- newValue
+	}))
+
+	it.Nil(ioutil.WriteFile(filePath, []byte(`{"app": {"tags": ["a", "c"]}}`), 0644))
+
+	select {
+	case newValue := <-changed:
+		it.Equal([]interface{}{"a", "c"}, newValue)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for config change notification")
+	}
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watcher bridges fsnotify file events to the callbacks registered via
+// Config.Watch, diffing the reloaded values against the previous ones
+// so only changed keys fire.
+type watcher struct {
+	config *Config
+	fsw    *fsnotify.Watcher
+
+	mux  sync.Mutex
+	subs map[string][]func(new, old interface{})
+}
+
+func newWatcher(config *Config) (*watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := map[string]bool{}
+	for _, filePath := range config.filePaths {
+		dirs[filepath.Dir(filePath)] = true
+	}
+	for dir := range dirs {
+		if err := fsw.Add(dir); err != nil {
+			fsw.Close()
+
+			return nil, err
+		}
+	}
+
+	w := &watcher{
+		config: config,
+		fsw:    fsw,
+		subs:   map[string][]func(new, old interface{}){},
+	}
+
+	go w.loop()
+
+	return w, nil
+}
+
+func (w *watcher) subscribe(key string, fn func(new, old interface{})) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	w.subs[key] = append(w.subs[key], fn)
+}
+
+func (w *watcher) loop() {
+	for {
+		select {
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+
+			if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+
+			w.reload()
+
+		case _, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+func (w *watcher) reload() {
+	values, old, err := w.config.reload()
+	if err != nil {
+		return
+	}
+
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	for key, fns := range w.subs {
+		newValue := values[key]
+		oldValue := old[key]
+
+		if reflect.DeepEqual(newValue, oldValue) {
+			continue
+		}
+
+		for _, fn := range fns {
+			fn(newValue, oldValue)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package hooks
+
+// ConfigChangedHook is invoked whenever Key's watched config value
+// changes, mirroring the NamedHook family used by the request lifecycle
+// (RequestReceivedHooks, ResponseReadyHooks, ResponseAlwaysHooks, ...)
+// so config hot-reload is as observable as request handling.
+type ConfigChangedHook struct {
+	Name string
+	Key  string
+
+	Apply func(key string, newValue, oldValue interface{})
+}
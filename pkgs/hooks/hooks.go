@@ -0,0 +1,14 @@
+package hooks
+
+import "net/http"
+
+// NamedHook is a single named hook in a request lifecycle family
+// (RequestReceivedHooks, RequestRoutedHooks, ResponseReadyHooks,
+// ResponseAlwaysHooks, ...), discovered independently via type
+// assertion on a Service by AppServer.NewService. Apply returns false
+// to short-circuit the remaining hooks in its family for this request.
+type NamedHook struct {
+	Name string
+
+	Apply func(w http.ResponseWriter, r *http.Request) bool
+}
@@ -0,0 +1,450 @@
+package gogo
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/dolab/gogo/pkgs/hooks"
+	"google.golang.org/grpc"
+)
+
+// GogoHealthz is the path AppServer answers unconditionally with a bare
+// 200, bypassing route dispatch and every hook family, for load
+// balancer health checks.
+const GogoHealthz = "/healthz"
+
+// Configer supplies configuration values to a Service's Init, e.g. the
+// *config.Config produced by pkgs/config.Loader.Load, or NewAppServer
+// itself (which reads "network"/"address" off it to know what Run
+// should bind). Watch lets a Service hook into hot-reload, firing fn
+// whenever key's value changes; it's the same signature as
+// pkgs/config.Config.Watch.
+type Configer interface {
+	Get(key string) interface{}
+	Watch(key string, fn func(newValue, oldValue interface{})) error
+}
+
+// HandlerFunc is the signature every route and middleware is registered
+// with.
+type HandlerFunc func(ctx *Context)
+
+// Grouper registers routes and middlewares under a path prefix, handed
+// to a Service's Init so it can mount its own routes without reaching
+// into AppServer directly.
+type Grouper interface {
+	Use(middlewares ...HandlerFunc)
+	Handle(method, path string, handler HandlerFunc)
+	GET(path string, handler HandlerFunc)
+	POST(path string, handler HandlerFunc)
+	PUT(path string, handler HandlerFunc)
+	PATCH(path string, handler HandlerFunc)
+	DELETE(path string, handler HandlerFunc)
+}
+
+// Service is the unit a project organizes its routes into. NewService
+// wires one into an AppServer by calling Init, then Middlewares, then
+// Resources, in that order.
+type Service interface {
+	Init(config Configer, group Grouper)
+	Middlewares()
+	Resources()
+}
+
+// requestReceivedHooker, requestRoutedHooker, responseReadyHooker and
+// responseAlwaysHooker are the hook families a Service may optionally
+// implement; NewService discovers each independently via type
+// assertion, the same way it discovers GRPCer.
+type requestReceivedHooker interface {
+	RequestReceivedHooks() []hooks.NamedHook
+}
+
+type requestRoutedHooker interface {
+	RequestRoutedHooks() []hooks.NamedHook
+}
+
+type responseReadyHooker interface {
+	ResponseReadyHooks() []hooks.NamedHook
+}
+
+type responseAlwaysHooker interface {
+	ResponseAlwaysHooks() []hooks.NamedHook
+}
+
+// configChangedHooker is the hook family a Service may optionally
+// implement to observe config hot-reload; NewService discovers it the
+// same way it discovers the request lifecycle hook families, registering
+// each returned hooks.ConfigChangedHook against s.config.Watch.
+type configChangedHooker interface {
+	ConfigChangedHooks() []hooks.ConfigChangedHook
+}
+
+// Context wraps a single request's ResponseWriter/Request pair through
+// a chain of middlewares and its final handler.
+type Context struct {
+	Writer  http.ResponseWriter
+	Request *http.Request
+
+	handlers []HandlerFunc
+	index    int
+}
+
+// Next invokes the next handler in the chain; a middleware calls it to
+// continue past itself, or omits the call to short-circuit the chain.
+func (ctx *Context) Next() {
+	ctx.index++
+	if ctx.index < len(ctx.handlers) {
+		ctx.handlers[ctx.index](ctx)
+	}
+}
+
+// SetStatus writes statusCode as the response's status line.
+func (ctx *Context) SetStatus(statusCode int) {
+	ctx.Writer.WriteHeader(statusCode)
+}
+
+// AddHeader appends value to the response header named key.
+func (ctx *Context) AddHeader(key, value string) {
+	ctx.Writer.Header().Add(key, value)
+}
+
+// Text writes body as a plain-text response.
+func (ctx *Context) Text(body string) {
+	ctx.Writer.Write([]byte(body))
+}
+
+// route is a single registered method+pattern. A pattern ending in
+// "/*name" (e.g. gateway.Service's "/*path") matches any path sharing
+// its literal prefix; every other pattern matches only that exact path.
+type route struct {
+	method  string
+	pattern string
+
+	handlers []HandlerFunc
+}
+
+func wildcardPrefix(pattern string) (string, bool) {
+	idx := strings.LastIndex(pattern, "/*")
+	if idx < 0 {
+		return "", false
+	}
+
+	return pattern[:idx+1], true
+}
+
+// group implements Grouper, mounting a Service's routes under prefix
+// with its own middleware chain.
+type group struct {
+	server *AppServer
+	prefix string
+
+	middlewares []HandlerFunc
+}
+
+func (g *group) Use(middlewares ...HandlerFunc) {
+	g.middlewares = append(g.middlewares, middlewares...)
+}
+
+func (g *group) Handle(method, path string, handler HandlerFunc) {
+	handlers := make([]HandlerFunc, 0, len(g.middlewares)+1)
+	handlers = append(handlers, g.middlewares...)
+	handlers = append(handlers, handler)
+
+	g.server.addRoute(method, g.prefix+path, handlers)
+}
+
+func (g *group) GET(path string, handler HandlerFunc) {
+	g.Handle(http.MethodGet, path, handler)
+}
+
+func (g *group) POST(path string, handler HandlerFunc) {
+	g.Handle(http.MethodPost, path, handler)
+}
+
+func (g *group) PUT(path string, handler HandlerFunc) {
+	g.Handle(http.MethodPut, path, handler)
+}
+
+func (g *group) PATCH(path string, handler HandlerFunc) {
+	g.Handle(http.MethodPatch, path, handler)
+}
+
+func (g *group) DELETE(path string, handler HandlerFunc) {
+	g.Handle(http.MethodDelete, path, handler)
+}
+
+// AppServer is the root HTTP(+gRPC) application server: it owns route
+// dispatch, the request-scoped logger pool and, once NewService has
+// registered a GRPCer, the grpc.Server multiplexed alongside HTTP by
+// the ServerOrchestrator Run delegates to.
+type AppServer struct {
+	config Configer
+	logger *AppLogger
+
+	network string
+	address string
+
+	mux          sync.RWMutex
+	routes       []route
+	grpcServer   *grpc.Server
+	orchestrator *ServerOrchestrator
+
+	requestReceivedHooks []hooks.NamedHook
+	requestRoutedHooks   []hooks.NamedHook
+	responseReadyHooks   []hooks.NamedHook
+	responseAlwaysHooks  []hooks.NamedHook
+}
+
+// NewAppServer returns an *AppServer reading its "network"/"address" to
+// listen on from config, e.g. the application.json keys the generated
+// project templates already ship.
+func NewAppServer(config Configer, logger *AppLogger) *AppServer {
+	server := &AppServer{
+		config: config,
+		logger: logger,
+	}
+
+	if network, ok := config.Get("network").(string); ok {
+		server.network = network
+	}
+	if address, ok := config.Get("address").(string); ok {
+		server.address = address
+	}
+
+	return server
+}
+
+func (s *AppServer) addRoute(method, path string, handlers []HandlerFunc) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.routes = append(s.routes, route{
+		method:   method,
+		pattern:  path,
+		handlers: handlers,
+	})
+}
+
+func (s *AppServer) matchRoute(method, path string) (route, bool) {
+	s.mux.RLock()
+	defer s.mux.RUnlock()
+
+	for _, rt := range s.routes {
+		if rt.method == method && rt.pattern == path {
+			return rt, true
+		}
+	}
+
+	for _, rt := range s.routes {
+		if rt.method != method {
+			continue
+		}
+
+		if prefix, ok := wildcardPrefix(rt.pattern); ok && strings.HasPrefix(path, prefix) {
+			return rt, true
+		}
+	}
+
+	return route{}, false
+}
+
+// GET registers handler for path, bypassing Grouper for callers that
+// don't need a Service (e.g. tests).
+func (s *AppServer) GET(path string, handler HandlerFunc) {
+	s.addRoute(http.MethodGet, path, []HandlerFunc{handler})
+}
+
+func (s *AppServer) POST(path string, handler HandlerFunc) {
+	s.addRoute(http.MethodPost, path, []HandlerFunc{handler})
+}
+
+func (s *AppServer) PUT(path string, handler HandlerFunc) {
+	s.addRoute(http.MethodPut, path, []HandlerFunc{handler})
+}
+
+func (s *AppServer) PATCH(path string, handler HandlerFunc) {
+	s.addRoute(http.MethodPatch, path, []HandlerFunc{handler})
+}
+
+func (s *AppServer) DELETE(path string, handler HandlerFunc) {
+	s.addRoute(http.MethodDelete, path, []HandlerFunc{handler})
+}
+
+// GRPCServer lazily builds the *grpc.Server every GRPCer discovered by
+// NewService registers itself against, installing
+// NewGRPCUnaryInterceptor so gRPC handlers share the same pooled,
+// tagged Logger as HTTP ones.
+func (s *AppServer) GRPCServer() *grpc.Server {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if s.grpcServer == nil {
+		s.grpcServer = grpc.NewServer(grpc.UnaryInterceptor(NewGRPCUnaryInterceptor(s.logger)))
+	}
+
+	return s.grpcServer
+}
+
+// NewService wires service into s: Init receives s's Configer and a
+// fresh Grouper mounted at the root, then Middlewares and Resources
+// register its routes. NewService also type-asserts service against
+// GRPCer and the four request lifecycle hook family interfaces,
+// registering whichever of them it implements, mirroring how each is
+// discovered independently rather than requiring one do-everything
+// interface.
+func (s *AppServer) NewService(service Service) {
+	grp := &group{server: s}
+
+	service.Init(s.config, grp)
+	service.Middlewares()
+	service.Resources()
+
+	if grpcer, ok := service.(GRPCer); ok {
+		grpcer.RegisterGRPC(s.GRPCServer())
+	}
+
+	if hooker, ok := service.(requestReceivedHooker); ok {
+		s.requestReceivedHooks = append(s.requestReceivedHooks, hooker.RequestReceivedHooks()...)
+	}
+	if hooker, ok := service.(requestRoutedHooker); ok {
+		s.requestRoutedHooks = append(s.requestRoutedHooks, hooker.RequestRoutedHooks()...)
+	}
+	if hooker, ok := service.(responseReadyHooker); ok {
+		s.responseReadyHooks = append(s.responseReadyHooks, hooker.ResponseReadyHooks()...)
+	}
+	if hooker, ok := service.(responseAlwaysHooker); ok {
+		s.responseAlwaysHooks = append(s.responseAlwaysHooks, hooker.ResponseAlwaysHooks()...)
+	}
+
+	if hooker, ok := service.(configChangedHooker); ok {
+		for _, hook := range hooker.ConfigChangedHooks() {
+			hook := hook
+
+			if err := s.config.Watch(hook.Key, func(newValue, oldValue interface{}) {
+				hook.Apply(hook.Key, newValue, oldValue)
+			}); err != nil {
+				log.Panicf("gogo: config.Watch(%s) for hook %s: %v\n", hook.Key, hook.Name, err)
+			}
+		}
+	}
+}
+
+func (s *AppServer) loggerNew(requestID string) Logger {
+	return s.logger.New(requestID)
+}
+
+func (s *AppServer) loggerReuse(lg Logger) {
+	s.logger.Reuse(lg)
+}
+
+// ServeHTTP answers GogoHealthz unconditionally, otherwise runs the
+// request through the matched route's handler chain, bracketed by
+// whatever RequestReceivedHooks/RequestRoutedHooks/ResponseReadyHooks/
+// ResponseAlwaysHooks were registered via NewService.
+func (s *AppServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == GogoHealthz {
+		w.WriteHeader(http.StatusOK)
+
+		return
+	}
+
+	for _, hook := range s.requestReceivedHooks {
+		if !hook.Apply(w, r) {
+			return
+		}
+	}
+
+	rt, ok := s.matchRoute(r.Method, r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	for _, hook := range s.requestRoutedHooks {
+		if !hook.Apply(w, r) {
+			return
+		}
+	}
+
+	alog := s.loggerNew(r.Header.Get("X-Request-Id"))
+	defer func() {
+		for _, hook := range s.responseAlwaysHooks {
+			hook.Apply(w, r)
+		}
+
+		s.loggerReuse(alog)
+	}()
+
+	ctx := &Context{
+		Writer:   w,
+		Request:  r.WithContext(context.WithValue(r.Context(), ctxLoggerKey, alog)),
+		handlers: rt.handlers,
+		index:    -1,
+	}
+	ctx.Next()
+
+	for _, hook := range s.responseReadyHooks {
+		if !hook.Apply(w, r) {
+			break
+		}
+	}
+}
+
+// Run binds and serves s's configured network/address, blocking until
+// Shutdown is called or a fatal error occurs. It's a thin wrapper
+// around a ServerOrchestrator with a single ListenerSpec, carrying
+// along whatever *grpc.Server NewService built so HTTP and gRPC are
+// multiplexed on the same listener.
+func (s *AppServer) Run() error {
+	s.mux.RLock()
+	spec := ListenerSpec{
+		Network:    s.network,
+		Address:    s.address,
+		GRPCServer: s.grpcServer,
+	}
+	s.mux.RUnlock()
+
+	orchestrator := NewServerOrchestrator(s.logger, s, spec)
+
+	s.mux.Lock()
+	s.orchestrator = orchestrator
+	s.mux.Unlock()
+
+	return orchestrator.Serve()
+}
+
+// Address returns the bound address once Run has started listening, or
+// "" before that.
+func (s *AppServer) Address() string {
+	s.mux.RLock()
+	orchestrator := s.orchestrator
+	s.mux.RUnlock()
+
+	if orchestrator == nil {
+		return ""
+	}
+
+	addrs := orchestrator.Addresses()
+	if len(addrs) == 0 {
+		return ""
+	}
+
+	return addrs[0]
+}
+
+// Shutdown gracefully drains the orchestrator Run started, within ctx's
+// deadline.
+func (s *AppServer) Shutdown(ctx context.Context) error {
+	s.mux.RLock()
+	orchestrator := s.orchestrator
+	s.mux.RUnlock()
+
+	if orchestrator == nil {
+		return nil
+	}
+
+	return orchestrator.Shutdown(ctx)
+}
@@ -0,0 +1,48 @@
+// +build !race
+
+package gogo
+
+import (
+	"github.com/dolab/gogo/pkgs/config"
+)
+
+// fakeHealthzServer returns an *AppServer bound to an ephemeral TCP port
+// with no routes registered, so only GogoHealthz answers.
+func fakeHealthzServer() *AppServer {
+	return NewAppServer(fakeServerConfig{
+		"network": "tcp",
+		"address": "127.0.0.1:0",
+	}, NewAppLogger("nil", ""))
+}
+
+// fakeTcpServer returns an *AppServer bound to an ephemeral TCP port.
+func fakeTcpServer() *AppServer {
+	return NewAppServer(fakeServerConfig{
+		"network": "tcp",
+		"address": "127.0.0.1:0",
+	}, NewAppLogger("nil", ""))
+}
+
+// fakeServer is an alias for fakeTcpServer, used by tests and benchmarks
+// that don't care which transport they're exercising.
+func fakeServer() *AppServer {
+	return fakeTcpServer()
+}
+
+// fakeUnixServer returns an *AppServer bound to a Unix socket at
+// /tmp/gogo.sock; callers are responsible for removing the socket file
+// once they're done with it.
+func fakeUnixServer() *AppServer {
+	return NewAppServer(fakeServerConfig{
+		"network": "unix",
+		"address": "/tmp/gogo.sock",
+	}, NewAppLogger("nil", ""))
+}
+
+// fakeConfig loads filename from testdata as a Configer, the same way a
+// project's own application.json is loaded via config.NewLoader.
+func fakeConfig(filename string) (Configer, error) {
+	return config.NewLoader().
+		WithProvider(config.NewFileProvider("testdata/" + filename)).
+		Load()
+}
@@ -0,0 +1,383 @@
+package gogo
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pires/go-proxyproto"
+	"github.com/soheilhy/cmux"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+)
+
+// Lifecycle describes the state of a listener managed by a
+// ServerOrchestrator.
+type Lifecycle int32
+
+// Lifecycle states, always entered in order: a listener is Created, then
+// Starting while its socket is being bound, Running once it's accepting
+// connections, Draining while Shutdown waits for in-flight connections
+// to finish and finally Stopped.
+const (
+	LifecycleCreated Lifecycle = iota
+	LifecycleStarting
+	LifecycleRunning
+	LifecycleDraining
+	LifecycleStopped
+)
+
+func (lc Lifecycle) String() string {
+	switch lc {
+	case LifecycleCreated:
+		return "created"
+	case LifecycleStarting:
+		return "starting"
+	case LifecycleRunning:
+		return "running"
+	case LifecycleDraining:
+		return "draining"
+	case LifecycleStopped:
+		return "stopped"
+	}
+
+	return "unknown"
+}
+
+// ListenerSpec describes a single endpoint a ServerOrchestrator should
+// bind and serve.
+type ListenerSpec struct {
+	// Network is passed to net.Listen, e.g. "tcp", "tcp4" or "unix".
+	Network string
+
+	// Address is passed to net.Listen, e.g. ":8080" or "/tmp/gogo.sock".
+	Address string
+
+	// TLSConfig enables TLS termination on this listener when non-nil.
+	TLSConfig *tls.Config
+
+	// ProxyProtocol accepts the PROXY protocol v1/v2 preamble on this
+	// listener, exposing the real client address to handlers.
+	ProxyProtocol bool
+
+	// GRPCServer, when set, is served on the same socket as the HTTP
+	// handler: connections are multiplexed by content-type so a single
+	// TCP listener can answer both JSON and unary gRPC calls.
+	GRPCServer *grpc.Server
+}
+
+// LifecycleHook is invoked by a ServerOrchestrator whenever one of its
+// listeners transitions into state.
+type LifecycleHook func(spec ListenerSpec) error
+
+// orchestratedListener tracks the runtime state of a single ListenerSpec.
+type orchestratedListener struct {
+	spec ListenerSpec
+
+	listener net.Listener
+	server   *http.Server
+	cmux     cmux.CMux
+
+	mux   sync.RWMutex
+	state Lifecycle
+}
+
+func (ol *orchestratedListener) Lifecycle() Lifecycle {
+	ol.mux.RLock()
+	defer ol.mux.RUnlock()
+
+	return ol.state
+}
+
+// ServerOrchestrator binds and serves multiple ListenerSpecs
+// simultaneously (e.g. HTTP + HTTPS + a Unix socket), tracking each
+// listener's Lifecycle independently and draining them together on
+// Shutdown. It is meant to sit alongside AppServer: AppServer.Run()
+// remains a thin single-listener wrapper around a ServerOrchestrator
+// with one ListenerSpec for callers who don't need multiplexing.
+type ServerOrchestrator struct {
+	logger  Logger
+	handler http.Handler
+
+	shutdownTimeout time.Duration
+
+	mux       sync.RWMutex
+	listeners []*orchestratedListener
+	hooks     map[Lifecycle][]LifecycleHook
+}
+
+// NewServerOrchestrator returns a *ServerOrchestrator that will serve
+// handler on every given spec once Serve is called.
+func NewServerOrchestrator(logger Logger, handler http.Handler, specs ...ListenerSpec) *ServerOrchestrator {
+	listeners := make([]*orchestratedListener, 0, len(specs))
+	for _, spec := range specs {
+		listeners = append(listeners, &orchestratedListener{
+			spec: spec,
+		})
+	}
+
+	return &ServerOrchestrator{
+		logger:          logger,
+		handler:         handler,
+		shutdownTimeout: 30 * time.Second,
+		listeners:       listeners,
+		hooks:           map[Lifecycle][]LifecycleHook{},
+	}
+}
+
+// RegisterLifecycleHook registers fn to run whenever any managed
+// listener transitions into state. Hooks run synchronously in
+// registration order and the transition fails fast on the first error.
+func (so *ServerOrchestrator) RegisterLifecycleHook(state Lifecycle, fn LifecycleHook) {
+	so.mux.Lock()
+	defer so.mux.Unlock()
+
+	so.hooks[state] = append(so.hooks[state], fn)
+}
+
+// Addresses returns the bound address of every listener that has
+// finished starting, in spec order. Tests should poll this (it stays
+// empty until Serve has bound the corresponding socket).
+func (so *ServerOrchestrator) Addresses() []string {
+	so.mux.RLock()
+	defer so.mux.RUnlock()
+
+	addrs := make([]string, 0, len(so.listeners))
+	for _, ol := range so.listeners {
+		if ol.listener == nil {
+			continue
+		}
+
+		addrs = append(addrs, ol.listener.Addr().String())
+	}
+
+	return addrs
+}
+
+// Serve binds every ListenerSpec and blocks until one of them returns a
+// fatal error or Shutdown drains them all, returning the first fatal
+// error (if any) via errgroup. A ListenerSpec that fails partway through
+// (a bad transition hook or a bind error) tears down every listener
+// already started in an earlier iteration instead of leaving their
+// bound sockets serving with nothing left to call Shutdown on them.
+func (so *ServerOrchestrator) Serve() error {
+	group, _ := errgroup.WithContext(context.Background())
+
+	so.mux.RLock()
+	listeners := append([]*orchestratedListener{}, so.listeners...)
+	so.mux.RUnlock()
+
+	started := make([]*orchestratedListener, 0, len(listeners))
+
+	for _, ol := range listeners {
+		ol := ol
+
+		if err := so.transition(ol, LifecycleCreated); err != nil {
+			so.stopStarted(started)
+
+			return err
+		}
+
+		if err := so.transition(ol, LifecycleStarting); err != nil {
+			so.stopStarted(started)
+
+			return err
+		}
+
+		listener, err := so.listen(ol.spec)
+		if err != nil {
+			so.stopStarted(started)
+
+			return err
+		}
+
+		ol.listener = listener
+		ol.server = &http.Server{
+			Handler:   so.handler,
+			TLSConfig: ol.spec.TLSConfig,
+		}
+
+		if err := so.transition(ol, LifecycleRunning); err != nil {
+			so.stopStarted(started)
+
+			return err
+		}
+
+		started = append(started, ol)
+
+		if ol.spec.GRPCServer == nil {
+			group.Go(func() error {
+				err := ol.server.Serve(ol.listener)
+				if err != nil && err != http.ErrServerClosed {
+					return err
+				}
+
+				return nil
+			})
+
+			continue
+		}
+
+		// multiplex gRPC (HTTP/2 + "application/grpc") and plain
+		// HTTP/1.1 on the same socket.
+		ol.cmux = cmux.New(ol.listener)
+		grpcListener := ol.cmux.MatchWithWriters(cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc"))
+		httpListener := ol.cmux.Match(cmux.Any())
+
+		group.Go(func() error {
+			err := ol.spec.GRPCServer.Serve(grpcListener)
+			if err != nil && err != cmux.ErrListenerClosed && err != grpc.ErrServerStopped {
+				return err
+			}
+
+			return nil
+		})
+		group.Go(func() error {
+			err := ol.server.Serve(httpListener)
+			if err != nil && err != http.ErrServerClosed {
+				return err
+			}
+
+			return nil
+		})
+		group.Go(func() error {
+			err := ol.cmux.Serve()
+			if err != nil && err != cmux.ErrListenerClosed && !errors.Is(err, net.ErrClosed) {
+				return err
+			}
+
+			return nil
+		})
+	}
+
+	return group.Wait()
+}
+
+// stopStarted force-stops every listener in started, used by Serve when
+// a later ListenerSpec fails to come up: the earlier ones are already
+// Running with real bound sockets and group.Go goroutines serving them,
+// and nothing else will ever call Shutdown to stop them.
+func (so *ServerOrchestrator) stopStarted(started []*orchestratedListener) {
+	for _, ol := range started {
+		if ol.spec.GRPCServer != nil {
+			ol.spec.GRPCServer.Stop()
+		}
+
+		if ol.server != nil {
+			ol.server.Close()
+		}
+
+		if ol.listener != nil {
+			ol.listener.Close()
+		}
+	}
+}
+
+// Shutdown drains every listener with ctx's deadline, transitioning each
+// through Draining and then Stopped once its in-flight connections have
+// finished. If the deadline expires first, Shutdown returns that error
+// and the listeners are left Draining.
+func (so *ServerOrchestrator) Shutdown(ctx context.Context) error {
+	so.mux.RLock()
+	listeners := append([]*orchestratedListener{}, so.listeners...)
+	so.mux.RUnlock()
+
+	group, gctx := errgroup.WithContext(ctx)
+
+	for _, ol := range listeners {
+		ol := ol
+
+		if err := so.transition(ol, LifecycleDraining); err != nil {
+			return err
+		}
+
+		group.Go(func() error {
+			if ol.spec.GRPCServer != nil {
+				// GracefulStop blocks until every in-flight RPC finishes,
+				// with no awareness of gctx's deadline, so a single
+				// long-lived streaming call could otherwise hang
+				// Shutdown well past it; race it against gctx and fall
+				// back to the hard Stop once it expires.
+				stopped := make(chan struct{})
+				go func() {
+					ol.spec.GRPCServer.GracefulStop()
+					close(stopped)
+				}()
+
+				select {
+				case <-stopped:
+				case <-gctx.Done():
+					ol.spec.GRPCServer.Stop()
+				}
+			}
+
+			if ol.server != nil {
+				if err := ol.server.Shutdown(gctx); err != nil {
+					return err
+				}
+			}
+
+			if ol.spec.GRPCServer == nil || ol.listener == nil {
+				return nil
+			}
+
+			// ol.server.Shutdown/GracefulStop above only close the
+			// matched virtual listeners cmux handed them; the real
+			// root listener is still blocked accepting inside the
+			// cmux.Serve() goroutine Serve started and must be closed
+			// directly or that goroutine leaks forever.
+			return ol.listener.Close()
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return err
+	}
+
+	for _, ol := range listeners {
+		if err := so.transition(ol, LifecycleStopped); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (so *ServerOrchestrator) listen(spec ListenerSpec) (net.Listener, error) {
+	listener, err := net.Listen(spec.Network, spec.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	if spec.ProxyProtocol {
+		listener = &proxyproto.Listener{Listener: listener}
+	}
+
+	if spec.TLSConfig != nil {
+		listener = tls.NewListener(listener, spec.TLSConfig)
+	}
+
+	return listener, nil
+}
+
+func (so *ServerOrchestrator) transition(ol *orchestratedListener, state Lifecycle) error {
+	so.mux.RLock()
+	hooks := so.hooks[state]
+	so.mux.RUnlock()
+
+	for _, hook := range hooks {
+		if err := hook(ol.spec); err != nil {
+			return err
+		}
+	}
+
+	ol.mux.Lock()
+	ol.state = state
+	ol.mux.Unlock()
+
+	return nil
+}
@@ -0,0 +1,127 @@
+// +build !race
+
+package gogo
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golib/assert"
+	"google.golang.org/grpc"
+)
+
+func Test_ServerOrchestrator_Serve(t *testing.T) {
+	it := assert.New(t)
+
+	var transitions []Lifecycle
+
+	orchestrator := NewServerOrchestrator(NewAppLogger("nil", ""), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotImplemented)
+	}), ListenerSpec{
+		Network: "tcp",
+		Address: "127.0.0.1:0",
+	})
+
+	for _, state := range []Lifecycle{LifecycleStarting, LifecycleRunning, LifecycleDraining, LifecycleStopped} {
+		state := state
+
+		orchestrator.RegisterLifecycleHook(state, func(spec ListenerSpec) error {
+			transitions = append(transitions, state)
+
+			return nil
+		})
+	}
+
+	go orchestrator.Serve()
+	for {
+		if len(orchestrator.Addresses()) > 0 {
+			break
+		}
+	}
+
+	addrs := orchestrator.Addresses()
+	it.Len(addrs, 1)
+
+	client := &http.Client{Timeout: time.Second}
+	response, err := client.Get("http://" + addrs[0])
+	if it.Nil(err) {
+		defer response.Body.Close()
+
+		it.Equal(http.StatusNotImplemented, response.StatusCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	it.Nil(orchestrator.Shutdown(ctx))
+	it.Equal([]Lifecycle{LifecycleStarting, LifecycleRunning, LifecycleDraining, LifecycleStopped}, transitions)
+}
+
+// Test_ServerOrchestrator_Serve_partialTeardown asserts that when a later
+// ListenerSpec fails to bind, every earlier ListenerSpec already Running
+// gets its socket closed too, instead of being left serving forever with
+// nothing left to call Shutdown on it.
+func Test_ServerOrchestrator_Serve_partialTeardown(t *testing.T) {
+	it := assert.New(t)
+
+	orchestrator := NewServerOrchestrator(NewAppLogger("nil", ""), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotImplemented)
+	}), ListenerSpec{
+		Network: "tcp",
+		Address: "127.0.0.1:0",
+	}, ListenerSpec{
+		Network: "tcp",
+		Address: "not-a-valid-address",
+	})
+
+	err := orchestrator.Serve()
+	it.NotNil(err)
+
+	addrs := orchestrator.Addresses()
+	it.Len(addrs, 1)
+
+	client := &http.Client{Timeout: time.Second}
+	_, err = client.Get("http://" + addrs[0])
+	it.NotNil(err, "the first listener should have been torn down alongside the second's bind failure")
+}
+
+// Test_ServerOrchestrator_ShutdownGRPC covers the cmux-multiplexed path
+// Test_ServerOrchestrator_Serve doesn't exercise: Shutdown must close
+// the listener's underlying root net.Listener itself, or the cmux.Serve
+// goroutine Serve spawned stays blocked in Accept() forever and this
+// test hangs until its own deadline fires.
+func Test_ServerOrchestrator_ShutdownGRPC(t *testing.T) {
+	it := assert.New(t)
+
+	orchestrator := NewServerOrchestrator(NewAppLogger("nil", ""), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotImplemented)
+	}), ListenerSpec{
+		Network:    "tcp",
+		Address:    "127.0.0.1:0",
+		GRPCServer: grpc.NewServer(),
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- orchestrator.Serve()
+	}()
+	for {
+		if len(orchestrator.Addresses()) > 0 {
+			break
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	it.Nil(orchestrator.Shutdown(ctx))
+
+	select {
+	case err := <-done:
+		it.Nil(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after Shutdown: cmux root listener was not closed")
+	}
+}
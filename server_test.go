@@ -0,0 +1,164 @@
+package gogo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dolab/gogo/pkgs/hooks"
+	"github.com/golib/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Test_Context_Next asserts a handler that doesn't call ctx.Next() short-
+// circuits the chain instead of every remaining handler still running.
+func Test_Context_Next(t *testing.T) {
+	it := assert.New(t)
+
+	var calls []int
+
+	ctx := &Context{
+		handlers: []HandlerFunc{
+			func(ctx *Context) {
+				calls = append(calls, 0)
+			},
+			func(ctx *Context) {
+				calls = append(calls, 1)
+
+				ctx.Next()
+			},
+			func(ctx *Context) {
+				calls = append(calls, 2)
+			},
+		},
+	}
+
+	ctx.handlers[0](ctx)
+	it.Equal([]int{0}, calls, "the chain must not advance when the current handler omits ctx.Next()")
+
+	calls = nil
+	ctx.index = 1
+	ctx.handlers[1](ctx)
+	it.Equal([]int{1, 2}, calls, "ctx.Next() should invoke only the single next handler")
+}
+
+type fakeServerConfig map[string]interface{}
+
+func (c fakeServerConfig) Get(key string) interface{} {
+	return c[key]
+}
+
+func (c fakeServerConfig) Watch(key string, fn func(newValue, oldValue interface{})) error {
+	return nil
+}
+
+// erroringConfig fails every Watch call, exercising NewService's
+// log.Panicf path for a configChangedHooker whose hook can't actually be
+// registered.
+type erroringConfig struct {
+	fakeServerConfig
+}
+
+func (c erroringConfig) Watch(key string, fn func(newValue, oldValue interface{})) error {
+	return errors.New("erroringConfig: watch always fails")
+}
+
+// hookedConfigService implements configChangedHooker with a single hook,
+// the minimal shape Test_AppServer_NewServiceWithConfigWatchError needs
+// to exercise NewService's config.Watch error handling.
+type hookedConfigService struct{}
+
+func (svc *hookedConfigService) Init(config Configer, group Grouper) {}
+func (svc *hookedConfigService) Middlewares()                        {}
+func (svc *hookedConfigService) Resources()                          {}
+
+func (svc *hookedConfigService) ConfigChangedHooks() []hooks.ConfigChangedHook {
+	return []hooks.ConfigChangedHook{
+		{
+			Name:  "app_name_changed@testing",
+			Key:   "app.name",
+			Apply: func(key string, newValue, oldValue interface{}) {},
+		},
+	}
+}
+
+// Test_AppServer_NewServiceWithConfigWatchError asserts NewService panics
+// (rather than silently dropping the hook) when config.Watch errors for
+// a configChangedHooker.
+func Test_AppServer_NewServiceWithConfigWatchError(t *testing.T) {
+	it := assert.New(t)
+
+	server := NewAppServer(erroringConfig{}, NewAppLogger("nil", ""))
+
+	defer func() {
+		it.NotNil(recover(), "NewService should panic when config.Watch fails for a configChangedHooker")
+	}()
+
+	server.NewService(&hookedConfigService{})
+}
+
+// fakeGRPCService implements both Service and GRPCer, the minimal shape
+// Test_AppServer_ServeHTTPAndGRPC needs to prove NewService wires a
+// GRPCer's *grpc.Server into the same listener Run serves HTTP on.
+type fakeGRPCService struct{}
+
+func (svc *fakeGRPCService) Init(config Configer, group Grouper) {}
+func (svc *fakeGRPCService) Middlewares()                        {}
+func (svc *fakeGRPCService) Resources()                          {}
+func (svc *fakeGRPCService) RegisterGRPC(s *grpc.Server)          {}
+
+// Test_AppServer_ServeHTTPAndGRPC asserts a JSON GET and a gRPC call
+// both succeed against the same address once NewService has registered
+// a GRPCer, proving the *grpc.Server GRPCServer builds is actually
+// reachable through Run rather than dead infrastructure sitting beside
+// it.
+//
+// NOTE: fakeGRPCService registers no methods (there's no protoc in this
+// environment to generate real stubs), so the gRPC call below targets a
+// method no server implements and asserts codes.Unimplemented -- that
+// still exercises cmux routing the call by content-type to *grpc.Server
+// (which answers an unknown method without running the unary
+// interceptor) rather than failing
+// at the TCP level the way it would if the listener weren't actually
+// multiplexing gRPC at all.
+func Test_AppServer_ServeHTTPAndGRPC(t *testing.T) {
+	it := assert.New(t)
+
+	server := NewAppServer(fakeServerConfig{
+		"network": "tcp",
+		"address": "127.0.0.1:0",
+	}, NewAppLogger("nil", ""))
+	server.GET("/server/ping", func(ctx *Context) {
+		ctx.Text("pong")
+	})
+	server.NewService(&fakeGRPCService{})
+
+	go server.Run()
+	for len(server.Address()) == 0 {
+	}
+	defer server.Shutdown(context.Background())
+
+	httpClient := &http.Client{Timeout: time.Second}
+	response, err := httpClient.Get("http://" + server.Address() + "/server/ping")
+	if it.Nil(err) {
+		defer response.Body.Close()
+
+		it.Equal(http.StatusOK, response.StatusCode)
+	}
+
+	dialCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	conn, err := grpc.DialContext(dialCtx, server.Address(), grpc.WithInsecure(), grpc.WithBlock())
+	if it.Nil(err) {
+		defer conn.Close()
+
+		err = conn.Invoke(context.Background(), "/gogo.test/Ping", &emptypb.Empty{}, &emptypb.Empty{})
+		it.Equal(codes.Unimplemented, status.Code(err))
+	}
+}